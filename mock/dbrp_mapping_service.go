@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/platform"
+)
+
+// DBRPMappingService is a mock implementation of platform.DBRPMappingService
+// whose behavior is configured by setting its Fn fields.
+type DBRPMappingService struct {
+	FindByFn   func(ctx context.Context, cluster, db, rp string) (*platform.DBRPMapping, error)
+	FindFn     func(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error)
+	FindManyFn func(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error)
+}
+
+// NewDBRPMappingService returns a mock DBRPMappingService with Fn fields
+// that return zero values; tests override the Fn fields they care about.
+func NewDBRPMappingService() *DBRPMappingService {
+	return &DBRPMappingService{
+		FindByFn: func(ctx context.Context, cluster, db, rp string) (*platform.DBRPMapping, error) {
+			return nil, nil
+		},
+		FindFn: func(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error) {
+			return nil, nil
+		},
+		FindManyFn: func(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error) {
+			return nil, 0, nil
+		},
+	}
+}
+
+func (s *DBRPMappingService) FindBy(ctx context.Context, cluster, db, rp string) (*platform.DBRPMapping, error) {
+	return s.FindByFn(ctx, cluster, db, rp)
+}
+
+func (s *DBRPMappingService) Find(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error) {
+	return s.FindFn(ctx, filter)
+}
+
+func (s *DBRPMappingService) FindMany(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error) {
+	return s.FindManyFn(ctx, filter, opt...)
+}
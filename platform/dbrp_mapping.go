@@ -0,0 +1,37 @@
+package platform
+
+import "context"
+
+// DBRPMapping represents a mapping of a database and retention policy to a
+// bucket and organization, used by the InfluxQL compatibility layer to
+// resolve the "database"/"retention policy" coordinates an InfluxQL query is
+// written against onto a Flux bucket.
+type DBRPMapping struct {
+	Cluster         string
+	Database        string
+	RetentionPolicy string
+	// Default indicates this mapping is the default retention policy for
+	// Database.
+	Default bool
+
+	OrganizationID ID
+	BucketID       ID
+}
+
+// DBRPMappingFilter represents a set of filters to find one or more mappings.
+type DBRPMappingFilter struct {
+	Cluster         *string
+	Database        *string
+	RetentionPolicy *string
+	Default         *bool
+	OrganizationID  *ID
+	BucketID        *ID
+}
+
+// DBRPMappingService maps a database and retention policy to a bucket and
+// organization.
+type DBRPMappingService interface {
+	FindBy(ctx context.Context, cluster, db, rp string) (*DBRPMapping, error)
+	Find(ctx context.Context, filter DBRPMappingFilter) (*DBRPMapping, error)
+	FindMany(ctx context.Context, filter DBRPMappingFilter, opt ...FindOptions) ([]*DBRPMapping, int, error)
+}
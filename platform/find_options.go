@@ -0,0 +1,10 @@
+package platform
+
+// FindOptions represents options passed to Find methods that support
+// pagination and sorting.
+type FindOptions struct {
+	Limit      int
+	Offset     int
+	SortBy     string
+	Descending bool
+}
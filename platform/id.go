@@ -0,0 +1,30 @@
+package platform
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// ID is a unique identifier for a platform resource. It is stored as the
+// hex encoding of 8 bytes.
+type ID [8]byte
+
+// String returns the hex encoding of the ID.
+func (id ID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IDFromString parses s, a 16 character hex string, into an ID.
+func IDFromString(s string) (*ID, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID %q: %w", s, err)
+	}
+	if len(b) != len(ID{}) {
+		return nil, fmt.Errorf("invalid ID %q: must be %d bytes", s, len(ID{}))
+	}
+
+	var id ID
+	copy(id[:], b)
+	return &id, nil
+}
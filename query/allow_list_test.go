@@ -0,0 +1,42 @@
+package query_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/influxql"
+)
+
+// TestBatchQuerier_WithAllowList exercises the allow-list against the list
+// committed at query/functions/testdata/allow.list, which holds the hash of
+// the nullable_values.influxql fixture.
+func TestBatchQuerier_WithAllowList(t *testing.T) {
+	listPath := filepath.Join("functions", "testdata", "allow.list")
+	list, err := influxql.LoadAllowList(listPath, influxql.AllowListStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowedQuery, err := ioutil.ReadFile(filepath.Join("functions", "testdata", "nullable_values.influxql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvIn, err := ioutil.ReadFile(filepath.Join("testdata", "simple_batch.in.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bq := &query.BatchQuerier{Querier: fixtureQuerier{csv: csvIn}}
+	bq.WithAllowList(list)
+
+	if _, err := bq.Do(context.Background(), fakeCompiler{query: string(allowedQuery)}); err != nil {
+		t.Errorf("expected an allow-listed query to run, got: %v", err)
+	}
+
+	if _, err := bq.Do(context.Background(), fakeCompiler{query: `SELECT * FROM "cpu"`}); err == nil {
+		t.Error("expected a query absent from the allow list to be rejected")
+	}
+}
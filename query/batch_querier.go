@@ -0,0 +1,303 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/platform/query/influxql"
+)
+
+// queryTexter is implemented by compilers that can report the query text
+// they were built from, e.g. *influxql.Compiler. It is how BatchQuerier
+// recovers the text to check against an AllowList, since flux.Compiler
+// itself carries no such accessor.
+type queryTexter interface {
+	QueryText() string
+}
+
+// BatchPoint is a single value recorded at a point in time within a Batch.
+type BatchPoint struct {
+	Time   time.Time
+	Fields map[string]interface{}
+}
+
+// Batch is a buffered, time-ordered set of points belonging to a single
+// series (a measurement plus its tag set). It is the unit a query is
+// chunked into when used as a batch data source, the same shape Kapacitor
+// passes between nodes in a batch TICKscript pipeline.
+type Batch struct {
+	Name   string
+	Tags   map[string]string
+	Points []BatchPoint
+}
+
+// BatchQuerier runs a Flux query on a fixed schedule and buffers its results
+// into Batches, so that a Flux query can be used as a batch data source
+// analogous to how Kapacitor wraps Flux in a `query()` node. Run drives the
+// schedule; Do executes a single round and is the building block Run calls.
+type BatchQuerier struct {
+	Querier
+
+	// Cluster names the InfluxDB cluster the underlying query targets. Run
+	// and Do do not read it; the compiler passed to them picks the cluster.
+	Cluster string
+
+	// Every is how often Run re-runs the query.
+	Every time.Duration
+
+	// Offset delays the start of each run by a fixed amount, so that points
+	// which arrive slightly late are still captured by the query window.
+	Offset time.Duration
+
+	// Period is the size of the trailing time window each run keeps;
+	// points older than Period (relative to the newest point in the same
+	// Batch) are dropped from the resulting Batches. Zero means no
+	// trimming is applied.
+	Period time.Duration
+
+	allowList *influxql.AllowList
+}
+
+// WithAllowList sets the allow list q checks a compiler's query text
+// against before running it, returning q for chaining. A compiler whose
+// query is not present in the list is rejected with a structured error
+// instead of reaching q.Querier; a compiler that does not implement
+// QueryText() string is rejected outright once an AllowList is set.
+func (q *BatchQuerier) WithAllowList(l *influxql.AllowList) *BatchQuerier {
+	q.allowList = l
+	return q
+}
+
+// Run executes compiler every q.Every, delayed by q.Offset, sending the
+// Batches produced by each round on out until ctx is canceled.
+func (q *BatchQuerier) Run(ctx context.Context, compiler flux.Compiler, out chan<- []Batch) error {
+	if q.Every <= 0 {
+		return fmt.Errorf("BatchQuerier.Every must be a positive duration")
+	}
+
+	ticker := time.NewTicker(q.Every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if q.Offset > 0 {
+				select {
+				case <-time.After(q.Offset):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			batches, err := q.Do(ctx, compiler)
+			if err != nil {
+				return err
+			}
+
+			select {
+			case out <- batches:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Do compiles and executes compiler against q.Querier and buffers the
+// resulting tables into time-ordered Batches, one per series, trimmed to
+// q.Period if it is set.
+//
+// Do always decodes the query through csv.DefaultDialect(); CSV/JSON dialect
+// negotiation is out of scope here, since Batch/BatchPoint are decoded from
+// the CSV result shape and a JSON path would need its own decode step rather
+// than a second Dialect passed straight through.
+func (q *BatchQuerier) Do(ctx context.Context, compiler flux.Compiler) ([]Batch, error) {
+	if q.allowList != nil {
+		texter, ok := compiler.(queryTexter)
+		if !ok {
+			return nil, fmt.Errorf("allow list requires a compiler exposing QueryText(), got %T", compiler)
+		}
+		if err := q.allowList.Allow(texter.QueryText()); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := q.Querier.Query(ctx, &buf, compiler, csv.DefaultDialect()); err != nil {
+		return nil, fmt.Errorf("batch query failed: %w", err)
+	}
+
+	decoder := csv.NewResultDecoder(csv.ResultDecoderConfig{})
+	results, err := decoder.Decode(ioutil.NopCloser(&buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode batch query results: %w", err)
+	}
+	defer results.Release()
+
+	var batches []Batch
+	for results.More() {
+		bs, err := ResultToBufferedBatches(results.Next())
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, bs...)
+	}
+	if err := results.Err(); err != nil {
+		return nil, err
+	}
+
+	if q.Period > 0 {
+		for i := range batches {
+			batches[i].Points = trimToPeriod(batches[i].Points, q.Period)
+		}
+	}
+	return batches, nil
+}
+
+// trimToPeriod drops every point older than period, measured back from the
+// most recent point in points.
+func trimToPeriod(points []BatchPoint, period time.Duration) []BatchPoint {
+	if len(points) == 0 {
+		return points
+	}
+
+	newest := points[len(points)-1].Time
+	for _, p := range points {
+		if p.Time.After(newest) {
+			newest = p.Time
+		}
+	}
+	cutoff := newest.Add(-period)
+
+	trimmed := points[:0:0]
+	for _, p := range points {
+		if !p.Time.Before(cutoff) {
+			trimmed = append(trimmed, p)
+		}
+	}
+	return trimmed
+}
+
+// ResultToBufferedBatches converts a flux.Result into one Batch per series
+// (per distinct measurement and tag set; tables whose group keys differ only
+// in _field, _start or _stop share a Batch), ordering each Batch's points by
+// time. Values in
+// the `_time` column are expected to be flux.Time and are converted to
+// time.Time along the way.
+func ResultToBufferedBatches(result flux.Result) ([]Batch, error) {
+	batchesByKey := make(map[string]*Batch)
+	var order []string
+
+	err := result.Tables().Do(func(tbl flux.Table) error {
+		name, tags := seriesFromKey(tbl.Key())
+		key := batchKey(name, tags)
+
+		b, ok := batchesByKey[key]
+		if !ok {
+			b = &Batch{Name: name, Tags: tags}
+			batchesByKey[key] = b
+			order = append(order, key)
+		}
+
+		cols := tbl.Cols()
+		timeIdx := -1
+		for i, c := range cols {
+			if c.Label == "_time" {
+				timeIdx = i
+				break
+			}
+		}
+		if timeIdx < 0 {
+			return fmt.Errorf("batch table %v is missing a _time column", tbl.Key())
+		}
+
+		return tbl.Do(func(cr flux.ColReader) error {
+			times := cr.Times(timeIdx)
+			for i := 0; i < cr.Len(); i++ {
+				fields := make(map[string]interface{}, len(cols)-1)
+				for j, c := range cols {
+					if j == timeIdx || tbl.Key().HasCol(c.Label) {
+						continue
+					}
+					fields[c.Label] = valueAt(cr, j, i)
+				}
+				b.Points = append(b.Points, BatchPoint{
+					Time:   times[i].Time(),
+					Fields: fields,
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(order)
+	batches := make([]Batch, 0, len(order))
+	for _, key := range order {
+		b := batchesByKey[key]
+		sort.Slice(b.Points, func(i, j int) bool {
+			return b.Points[i].Time.Before(b.Points[j].Time)
+		})
+		batches = append(batches, *b)
+	}
+	return batches, nil
+}
+
+func seriesFromKey(key flux.GroupKey) (string, map[string]string) {
+	name := "_"
+	tags := make(map[string]string)
+	for i, c := range key.Cols() {
+		switch c.Label {
+		case "_measurement":
+			name = key.ValueString(i)
+		case "_start", "_stop", "_time", "_field", "_value":
+			// Not a tag.
+		default:
+			tags[c.Label] = key.ValueString(i)
+		}
+	}
+	return name, tags
+}
+
+func batchKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	key := name
+	for _, k := range keys {
+		key += "," + k + "=" + tags[k]
+	}
+	return key
+}
+
+func valueAt(cr flux.ColReader, col, row int) interface{} {
+	switch cr.Cols()[col].Type {
+	case flux.TFloat:
+		return cr.Floats(col)[row]
+	case flux.TInt:
+		return cr.Ints(col)[row]
+	case flux.TUInt:
+		return cr.UInts(col)[row]
+	case flux.TString:
+		return cr.Strings(col)[row]
+	case flux.TBool:
+		return cr.Bools(col)[row]
+	case flux.TTime:
+		return cr.Times(col)[row].Time()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package query_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/platform/query"
+)
+
+// fixtureQuerier is a Querier that ignores the compiler it is given and
+// always serves back a fixed CSV fixture, the way Test_QueryEndToEnd drives
+// testFlux/testInfluxQL off of on-disk golden files.
+type fixtureQuerier struct {
+	csv []byte
+}
+
+func (q fixtureQuerier) Query(ctx context.Context, w io.Writer, compiler flux.Compiler, d flux.Dialect) (int64, error) {
+	n, err := w.Write(q.csv)
+	return int64(n), err
+}
+
+func Test_BatchQuerierEndToEnd(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "testdata")
+
+	fluxFiles, err := filepath.Glob(filepath.Join(path, "*.flux"))
+	if err != nil {
+		t.Fatalf("error searching for Flux files: %s", err)
+	}
+
+	for _, fluxFile := range fluxFiles {
+		ext := filepath.Ext(fluxFile)
+		prefix := fluxFile[0 : len(fluxFile)-len(ext)]
+		_, caseName := filepath.Split(prefix)
+
+		t.Run(caseName, func(t *testing.T) {
+			q, err := ioutil.ReadFile(fluxFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			csvIn, err := ioutil.ReadFile(prefix + ".in.csv")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ioutil.ReadFile(prefix + ".batches")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bq := &query.BatchQuerier{
+				Querier: fixtureQuerier{csv: csvIn},
+				Cluster: "cluster",
+				Every:   0,
+				Period:  0,
+			}
+
+			batches, err := bq.Do(context.Background(), fakeCompiler{query: string(q)})
+			if err != nil {
+				t.Fatalf("batch query failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			for _, b := range batches {
+				buf.WriteString(b.Name)
+				for _, p := range b.Points {
+					buf.WriteString(" ")
+					buf.WriteString(p.Time.UTC().Format("2006-01-02T15:04:05Z"))
+				}
+				buf.WriteString("\n")
+			}
+
+			if got, want := buf.String(), string(want); got != want {
+				t.Errorf("unexpected batches for %s:\ngot:\n%s\nwant:\n%s", caseName, got, want)
+			}
+		})
+	}
+}
+
+type fakeCompiler struct {
+	query string
+}
+
+func (c fakeCompiler) Compile(ctx context.Context) (flux.Program, error) {
+	return nil, nil
+}
+
+func (c fakeCompiler) CompilerType() flux.CompilerType {
+	return "fake"
+}
+
+// QueryText implements the unexported interface BatchQuerier checks a
+// compiler against once an AllowList is set.
+func (c fakeCompiler) QueryText() string {
+	return c.query
+}
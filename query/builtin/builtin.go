@@ -0,0 +1,8 @@
+// Package builtin is imported for its side effects. It imports no function
+// packages and its init registers no Flux functions or options.
+package builtin
+
+func init() {
+	// Built-in functions register themselves with Flux via their own
+	// init() functions as they are added under query/functions.
+}
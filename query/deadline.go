@@ -0,0 +1,90 @@
+package query
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDeadlineExceeded is returned when a query does not finish executing
+// before the read or write deadline set on its ProxyRequest.
+var ErrDeadlineExceeded = errors.New("deadline exceeded")
+
+// deadlineTimer is a re-armable read/write deadline, modeled on the
+// deadlineTimer netstack uses to bound how long a connection operation may
+// block. Its zero value has no deadline set.
+type deadlineTimer struct {
+	mu sync.Mutex
+
+	readTimer  *time.Timer
+	readCancel chan struct{}
+
+	writeTimer  *time.Timer
+	writeCancel chan struct{}
+}
+
+// setDeadline arms timer/cancel for t. A zero t disables the deadline; a t
+// that has already passed closes cancel immediately.
+func (d *deadlineTimer) setDeadline(timer **time.Timer, cancel *chan struct{}, t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Keep the current cancel channel, so that listeners see the re-armed
+	// deadline, unless a fired timer or a past deadline already closed it.
+	if *timer != nil && !(*timer).Stop() {
+		*cancel = nil
+	}
+	*timer = nil
+	select {
+	case <-*cancel:
+		*cancel = nil
+	default:
+	}
+	if *cancel == nil {
+		*cancel = make(chan struct{})
+	}
+
+	if t.IsZero() {
+		return
+	}
+
+	c := *cancel
+	if !t.After(time.Now()) {
+		close(c)
+		return
+	}
+	*timer = time.AfterFunc(time.Until(t), func() { close(c) })
+}
+
+// SetDeadline sets both the read and write deadlines, as with
+// net.Conn.SetDeadline. A zero time.Time disables the deadline.
+func (d *deadlineTimer) SetDeadline(t time.Time) {
+	d.SetReadDeadline(t)
+	d.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline bounding how long a query may spend
+// reading its input. A zero time.Time disables the deadline; a time already
+// in the past fires immediately.
+func (d *deadlineTimer) SetReadDeadline(t time.Time) {
+	d.setDeadline(&d.readTimer, &d.readCancel, t)
+}
+
+// SetWriteDeadline sets the deadline bounding how long a query may spend
+// writing its output. A zero time.Time disables the deadline; a time
+// already in the past fires immediately.
+func (d *deadlineTimer) SetWriteDeadline(t time.Time) {
+	d.setDeadline(&d.writeTimer, &d.writeCancel, t)
+}
+
+func (d *deadlineTimer) readCancelChannel() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.readCancel
+}
+
+func (d *deadlineTimer) writeCancelChannel() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.writeCancel
+}
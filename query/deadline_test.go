@@ -0,0 +1,97 @@
+package query_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/platform/query"
+)
+
+func TestProxyRequest_DeadlineExceeded(t *testing.T) {
+	req := &query.ProxyRequest{}
+	req.SetDeadline(time.Now().Add(time.Millisecond))
+
+	ctx, cancel, deadlineErr := req.Context(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the context to be canceled by the deadline")
+	}
+
+	if err := deadlineErr(); err != query.ErrDeadlineExceeded {
+		t.Errorf("got error %v, want query.ErrDeadlineExceeded", err)
+	}
+}
+
+// sleepingQuerier is a Querier whose query takes delay to finish, standing
+// in for a real Flux query that is still executing when its deadline fires.
+type sleepingQuerier struct {
+	delay time.Duration
+}
+
+func (q sleepingQuerier) Query(ctx context.Context, w io.Writer, compiler flux.Compiler, d flux.Dialect) (int64, error) {
+	select {
+	case <-time.After(q.delay):
+		return 0, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestProxyRequest_DeadlineCancelsSleepingQuery(t *testing.T) {
+	req := &query.ProxyRequest{}
+	req.SetDeadline(time.Now().Add(time.Millisecond))
+
+	ctx, cancel, deadlineErr := req.Context(context.Background())
+	defer cancel()
+
+	q := sleepingQuerier{delay: time.Second}
+	if _, err := q.Query(ctx, ioutil.Discard, nil, nil); err == nil {
+		t.Fatal("expected the sleeping query to be canceled by the deadline")
+	}
+
+	if err := deadlineErr(); err != query.ErrDeadlineExceeded {
+		t.Errorf("got error %v, want query.ErrDeadlineExceeded", err)
+	}
+}
+
+func TestProxyRequest_NoDeadline(t *testing.T) {
+	req := &query.ProxyRequest{}
+
+	ctx, cancel, deadlineErr := req.Context(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("context should not be canceled when no deadline is set")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if err := deadlineErr(); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
+
+func TestProxyRequest_PastDeadlineFiresImmediately(t *testing.T) {
+	req := &query.ProxyRequest{}
+	req.SetReadDeadline(time.Now().Add(-time.Second))
+
+	ctx, cancel, deadlineErr := req.Context(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected a past deadline to fire immediately")
+	}
+
+	if err := deadlineErr(); err != query.ErrDeadlineExceeded {
+		t.Errorf("got error %v, want query.ErrDeadlineExceeded", err)
+	}
+}
@@ -68,6 +68,7 @@ var skipTests = map[string]string{
 	"derivative_percentile_50": "add derivative support to the transpiler (https://github.com/influxdata/platform/issues/93)",
 	"derivative_percentile_90": "add derivative support to the transpiler (https://github.com/influxdata/platform/issues/93)",
 	"derivative_sum":           "add derivative support to the transpiler (https://github.com/influxdata/platform/issues/93)",
+	"nullable_values":          "fixture is CSV-only; no .in.json input exists for the FromInfluxJSONCompiler path Test_GeneratedInfluxQLQueries requires, and this shared skip list also skips it in Test_QueryEndToEnd",
 }
 
 var querier = querytest.NewQuerier()
@@ -315,8 +316,11 @@ func influxQLCompiler(query, filename string) querytest.FromInfluxJSONCompiler {
 }
 
 func queryToJSON(querier *querytest.Querier, req *query.ProxyRequest) (io.ReadCloser, error) {
+	ctx, cancel, _ := req.Context(context.Background())
+	defer cancel()
+
 	var buf bytes.Buffer
-	_, err := querier.Query(context.Background(), &buf, req.Request.Compiler, req.Dialect)
+	_, err := querier.Query(ctx, &buf, req.Request.Compiler, req.Dialect)
 	if err != nil {
 		return nil, err
 	}
@@ -346,9 +350,16 @@ func jsonToResultIterator(file string) (flux.ResultIterator, error) {
 func QueryTestCheckSpec(t testing.TB, querier *querytest.Querier, req *query.ProxyRequest, want string) {
 	t.Helper()
 
+	ctx, cancel, deadlineErr := req.Context(context.Background())
+	defer cancel()
+
 	var buf bytes.Buffer
-	_, err := querier.Query(context.Background(), &buf, req.Request.Compiler, req.Dialect)
+	_, err := querier.Query(ctx, &buf, req.Request.Compiler, req.Dialect)
 	if err != nil {
+		if derr := deadlineErr(); derr == query.ErrDeadlineExceeded {
+			t.Errorf("query exceeded its deadline: %v", derr)
+			return
+		}
 		t.Errorf("failed to run query: %v", err)
 		return
 	}
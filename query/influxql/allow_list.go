@@ -0,0 +1,152 @@
+package influxql
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AllowListMode controls how an AllowList behaves when it is asked about a
+// query it does not already contain.
+type AllowListMode int
+
+const (
+	// AllowListStrict rejects any query whose hash is not already present
+	// in the list. It is the mode CI runs in, so an un-vetted query fails
+	// the build instead of silently reaching production.
+	AllowListStrict AllowListMode = iota
+
+	// AllowListRecord appends newly seen queries to the list instead of
+	// rejecting them, so a developer can regenerate the list locally
+	// before committing it.
+	AllowListRecord
+)
+
+// AllowList is a persisted set of query hashes a Compiler is permitted to
+// run, borrowing the allow-list pattern GraphQL gateways use to lock a
+// production endpoint down to a vetted set of operations.
+type AllowList struct {
+	path string
+	mode AllowListMode
+
+	mu      sync.Mutex
+	allowed map[string]bool
+	dirty   bool
+}
+
+var allowListWhitespace = regexp.MustCompile(`\s+`)
+
+// hashQuery normalizes query (collapsing whitespace) and returns the hex
+// digest used as its key in the allow list, so that semantically identical
+// queries hash the same regardless of formatting.
+func hashQuery(query string) string {
+	normalized := allowListWhitespace.ReplaceAllString(strings.TrimSpace(query), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadAllowList reads the allow-list persisted at path in the given mode.
+// A missing file is treated as an empty list rather than an error, so that
+// CreateIfNotExists(path) can bootstrap a list that does not exist yet.
+func LoadAllowList(path string, mode AllowListMode) (*AllowList, error) {
+	l := &AllowList{path: path, mode: mode, allowed: make(map[string]bool)}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open allow list %q: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if h := strings.TrimSpace(scanner.Text()); h != "" {
+			l.allowed[h] = true
+		}
+	}
+	return l, scanner.Err()
+}
+
+// CreateIfNotExists loads the allow-list at path, creating an empty one on
+// disk first if it is missing.
+func CreateIfNotExists(path string, mode AllowListMode) (*AllowList, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			return nil, fmt.Errorf("failed to create allow list %q: %w", path, err)
+		}
+	}
+	return LoadAllowList(path, mode)
+}
+
+// Allow reports whether query is permitted to run. In AllowListRecord mode
+// an unseen query is added to the list and allowed; call Persist to write
+// the updated list back to disk. In AllowListStrict mode an unseen query
+// returns an *ErrQueryNotAllowed.
+func (l *AllowList) Allow(query string) error {
+	h := hashQuery(query)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.allowed[h] {
+		return nil
+	}
+
+	if l.mode != AllowListRecord {
+		return &ErrQueryNotAllowed{Query: query}
+	}
+
+	l.allowed[h] = true
+	l.dirty = true
+	return nil
+}
+
+// Persist writes the allow-list back to its backing file if it has grown
+// since it was loaded or last persisted.
+func (l *AllowList) Persist() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.dirty {
+		return nil
+	}
+
+	hashes := make([]string, 0, len(l.allowed))
+	for h := range l.allowed {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+
+	f, err := os.Create(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to persist allow list %q: %w", l.path, err)
+	}
+	defer f.Close()
+
+	for _, h := range hashes {
+		if _, err := fmt.Fprintln(f, h); err != nil {
+			return err
+		}
+	}
+
+	l.dirty = false
+	return nil
+}
+
+// ErrQueryNotAllowed is returned when a query's hash is not present in an
+// AllowList running in AllowListStrict mode.
+type ErrQueryNotAllowed struct {
+	Query string
+}
+
+func (e *ErrQueryNotAllowed) Error() string {
+	return fmt.Sprintf("query is not present in the allow list: %s", e.Query)
+}
@@ -0,0 +1,61 @@
+package influxql_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/platform/query/influxql"
+)
+
+func TestAllowList_StrictRejectsUnknownQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allow-list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "allow.list")
+	list, err := influxql.CreateIfNotExists(path, influxql.AllowListStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := list.Allow(`SELECT * FROM "cpu"`); err == nil {
+		t.Fatal("expected an unseen query to be rejected in strict mode")
+	}
+}
+
+func TestAllowList_RecordThenStrict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allow-list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "allow.list")
+	query := `SELECT * FROM "cpu"`
+
+	recorder, err := influxql.CreateIfNotExists(path, influxql.AllowListRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Allow(query); err != nil {
+		t.Fatalf("recording query should not fail: %v", err)
+	}
+	if err := recorder.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := influxql.LoadAllowList(path, influxql.AllowListStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := strict.Allow(query); err != nil {
+		t.Errorf("persisted query should be allowed, got: %v", err)
+	}
+	if err := strict.Allow(`SELECT * FROM "mem"`); err == nil {
+		t.Error("expected an unrecorded query to be rejected")
+	}
+}
@@ -0,0 +1,70 @@
+// Package influxql implements an InfluxQL compiler that transpiles an
+// InfluxQL query into Flux and executes it through the Flux engine, so that
+// InfluxQL clients can run unmodified against the platform.
+package influxql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/platform"
+)
+
+// Compiler transpiles an InfluxQL query into a Flux program. Its Cluster and
+// DB, with the default retention policy, are resolved to a bucket through a
+// platform.DBRPMappingService; database and retention policy qualifiers in
+// the query's FROM clause are not consulted.
+type Compiler struct {
+	Cluster string
+	DB      string
+	Query   string
+
+	// Templates holds Graphite-style templates used to decompose a dotted
+	// measurement name (e.g. "cpu.us-west.web01.idle") into tags and a
+	// field during transpilation. See NewTemplateMapper for the syntax.
+	Templates []string
+
+	dbrpMappingSvc platform.DBRPMappingService
+}
+
+// NewCompiler returns a Compiler that resolves database/retention policy
+// coordinates through dbrpMappingSvc.
+func NewCompiler(dbrpMappingSvc platform.DBRPMappingService) *Compiler {
+	return &Compiler{dbrpMappingSvc: dbrpMappingSvc}
+}
+
+// QueryText returns c.Query, satisfying the unexported interface a
+// query.Querier checks a Compiler against when it has been given an
+// AllowList.
+func (c *Compiler) QueryText() string {
+	return c.Query
+}
+
+// CompilerType reports the type of compiler, used by Flux to select the
+// compiler registered for a given query language.
+func (c *Compiler) CompilerType() flux.CompilerType {
+	return "influxql"
+}
+
+// Compile transpiles c.Query into a flux.Program.
+func (c *Compiler) Compile(ctx context.Context) (flux.Program, error) {
+	mapping, err := c.dbrpMappingSvc.FindBy(ctx, c.Cluster, c.DB, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve database %q on cluster %q: %w", c.DB, c.Cluster, err)
+	}
+
+	templates, err := NewTemplateMapper(c.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	t := newTranspiler(mapping, templates)
+	fluxQuery, err := t.Transpile(c.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transpile InfluxQL query: %w", err)
+	}
+
+	return lang.FluxCompiler{Query: fluxQuery}.Compile(ctx)
+}
@@ -0,0 +1,194 @@
+package influxql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/platform/query/influxql/null"
+)
+
+// Dialect encodes Flux results as InfluxQL-compatible JSON, the response
+// shape InfluxQL HTTP clients expect back from /query. Numeric columns are
+// encoded through null.Float/null.Int so that a missing value comes back as
+// a JSON `null` rather than being coerced to zero.
+//
+// This only covers the encoding half of the round trip: the decoder that
+// reads this shape back into Flux tables is ifql.NewResultDecoder in
+// github.com/influxdata/flux/influxql, and executetest.EqualResults, which
+// testGeneratedInfluxQL uses to compare decoded results, both live in the
+// upstream flux module and are out of scope for this repo.
+type Dialect struct{}
+
+// Encoder implements flux.Dialect.
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	return new(jsonEncoder)
+}
+
+// DialectType implements flux.Dialect.
+func (d *Dialect) DialectType() flux.DialectType {
+	return "influxql"
+}
+
+type influxQLResponse struct {
+	Results []influxQLResult `json:"results"`
+}
+
+type influxQLResult struct {
+	StatementID int              `json:"statement_id"`
+	Series      []influxQLSeries `json:"series,omitempty"`
+}
+
+type influxQLSeries struct {
+	Name    string            `json:"name,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+}
+
+type jsonEncoder struct{}
+
+func (e *jsonEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	var resp influxQLResponse
+
+	stmt := 0
+	for results.More() {
+		series, err := seriesFromResult(results.Next())
+		if err != nil {
+			return 0, err
+		}
+		resp.Results = append(resp.Results, influxQLResult{StatementID: stmt, Series: series})
+		stmt++
+	}
+	if err := results.Err(); err != nil {
+		return 0, err
+	}
+
+	cw := &countingWriter{w: w}
+	err := json.NewEncoder(cw).Encode(resp)
+	return cw.n, err
+}
+
+func seriesFromResult(res flux.Result) ([]influxQLSeries, error) {
+	var out []influxQLSeries
+	err := res.Tables().Do(func(tbl flux.Table) error {
+		s, err := seriesFromTable(tbl)
+		if err != nil {
+			return err
+		}
+		out = append(out, s)
+		return nil
+	})
+	return out, err
+}
+
+// seriesFromTable converts a single Flux table, grouped by tags and by
+// _field the way the transpiler emits it, into one InfluxQL series whose
+// first column is "time" and whose second column is named after the
+// table's _field value rather than the literal "_value" Flux uses.
+func seriesFromTable(tbl flux.Table) (influxQLSeries, error) {
+	cols := tbl.Cols()
+	name, tags := seriesFromKey(tbl.Key())
+	field := fieldFromKey(tbl.Key())
+
+	timeIdx, valueIdx := -1, -1
+	for i, c := range cols {
+		switch c.Label {
+		case "_time":
+			timeIdx = i
+		case "_value":
+			valueIdx = i
+		}
+	}
+	if timeIdx < 0 || valueIdx < 0 {
+		return influxQLSeries{}, fmt.Errorf("table %v is missing a _time/_value column", tbl.Key())
+	}
+
+	s := influxQLSeries{Name: name, Tags: tags, Columns: []string{"time", field}}
+	err := tbl.Do(func(cr flux.ColReader) error {
+		times := cr.Times(timeIdx)
+		for i := 0; i < cr.Len(); i++ {
+			t := times[i].Time().Format(time.RFC3339)
+			v := nullableValueAt(cr, valueIdx, i, cols[valueIdx].Type)
+			s.Values = append(s.Values, []interface{}{t, v})
+		}
+		return nil
+	})
+	return s, err
+}
+
+// seriesFromKey splits a table's group key into its InfluxQL series name
+// (the _measurement value) and its tags (every other column but the
+// InfluxDB-internal ones).
+func seriesFromKey(key flux.GroupKey) (string, map[string]string) {
+	name := "_"
+	tags := make(map[string]string)
+	for i, c := range key.Cols() {
+		switch c.Label {
+		case "_measurement":
+			name = key.ValueString(i)
+		case "_start", "_stop", "_time", "_field", "_value":
+			// Not a tag.
+		default:
+			tags[c.Label] = key.ValueString(i)
+		}
+	}
+	return name, tags
+}
+
+// fieldFromKey returns the table's _field group key value, or "value" if
+// the table is not grouped by field.
+func fieldFromKey(key flux.GroupKey) string {
+	for i, c := range key.Cols() {
+		if c.Label == "_field" {
+			return key.ValueString(i)
+		}
+	}
+	return "value"
+}
+
+// nullableValueAt reads the value at (col, row), returning a null.Float or
+// null.Int for numeric columns so that a missing value encodes as JSON
+// `null` instead of being coerced to 0. Missing numeric values are carried
+// through the CSV round trip as NaN (floats) or math.MinInt64 (ints), the
+// same sentinels the CSV decoder produces for an empty numeric field.
+func nullableValueAt(cr flux.ColReader, col, row int, typ flux.ColType) interface{} {
+	switch typ {
+	case flux.TFloat:
+		v := cr.Floats(col)[row]
+		if math.IsNaN(v) {
+			return null.Float{}
+		}
+		return null.FloatFrom(v)
+	case flux.TInt:
+		v := cr.Ints(col)[row]
+		if v == math.MinInt64 {
+			return null.Int{}
+		}
+		return null.IntFrom(v)
+	case flux.TUInt:
+		return null.IntFrom(int64(cr.UInts(col)[row]))
+	case flux.TString:
+		return cr.Strings(col)[row]
+	case flux.TBool:
+		return cr.Bools(col)[row]
+	case flux.TTime:
+		return cr.Times(col)[row].Time()
+	default:
+		return nil
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,70 @@
+// Package null provides nullable wrappers around the numeric types carried
+// in InfluxQL JSON responses, so that a JSON `null` can round-trip through a
+// Flux table without being coerced into a zero value.
+package null
+
+import "encoding/json"
+
+// Float is a float64 that may be null.
+type Float struct {
+	Float64 float64
+	Valid   bool
+}
+
+// FloatFrom wraps f as a valid, non-null Float.
+func FloatFrom(f float64) Float {
+	return Float{Float64: f, Valid: true}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f Float) MarshalJSON() ([]byte, error) {
+	if !f.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.Float64)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Float) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		f.Float64, f.Valid = 0, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &f.Float64); err != nil {
+		return err
+	}
+	f.Valid = true
+	return nil
+}
+
+// Int is an int64 that may be null.
+type Int struct {
+	Int64 int64
+	Valid bool
+}
+
+// IntFrom wraps i as a valid, non-null Int.
+func IntFrom(i int64) Int {
+	return Int{Int64: i, Valid: true}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (i Int) MarshalJSON() ([]byte, error) {
+	if !i.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(i.Int64)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *Int) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		i.Int64, i.Valid = 0, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &i.Int64); err != nil {
+		return err
+	}
+	i.Valid = true
+	return nil
+}
@@ -0,0 +1,141 @@
+package influxql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template is one Graphite-style template: a dot-separated pattern of
+// tag/measurement/field placeholders (with optional `*` wildcards) used to
+// decompose a dotted measurement name into tags, plus an optional filter
+// restricting which measurement names it applies to and default tags
+// applied whenever it matches.
+type Template struct {
+	// Parts are the dot-separated components of the pattern, e.g.
+	// ["region", "host", "measurement", "field"].
+	Parts []string
+
+	// Filter, if set, restricts this template to measurement names whose
+	// leading dotted components match it, e.g. a filter of ["prod", "*"]
+	// only matches names starting with "prod.".
+	Filter []string
+
+	// Tags are default tag values applied whenever this template matches,
+	// in addition to the tags captured from Parts.
+	Tags map[string]string
+}
+
+// TemplateMapper decomposes a dotted InfluxQL measurement name into a
+// measurement, a field and a set of tags, the way Graphite templates remap
+// dotted metric names onto tags.
+type TemplateMapper struct {
+	templates []*Template
+}
+
+// NewTemplateMapper parses templates, one Graphite-style template per
+// entry, e.g. "region.host.measurement.field" or
+// "env.measurement.field filter=prod.* dc=west". Templates are matched in
+// the order given, so more specific templates should come first.
+func NewTemplateMapper(templates []string) (*TemplateMapper, error) {
+	m := &TemplateMapper{}
+	for _, line := range templates {
+		t, err := parseTemplate(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", line, err)
+		}
+		m.templates = append(m.templates, t)
+	}
+	return m, nil
+}
+
+func parseTemplate(line string) (*Template, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty template")
+	}
+
+	t := &Template{Tags: make(map[string]string)}
+	t.Parts = strings.Split(fields[0], ".")
+
+	hasMeasurement := false
+	for _, p := range t.Parts {
+		if p == "measurement" {
+			hasMeasurement = true
+		}
+	}
+	if !hasMeasurement {
+		return nil, fmt.Errorf(`template must contain a "measurement" component`)
+	}
+
+	for _, f := range fields[1:] {
+		if rest, ok := cutPrefix(f, "filter="); ok {
+			t.Filter = strings.Split(rest, ".")
+			continue
+		}
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid template tag %q", f)
+		}
+		t.Tags[kv[0]] = kv[1]
+	}
+	return t, nil
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
+
+// Match decomposes name, a dotted measurement name, against m's templates
+// in order and returns the first one whose shape and filter match. ok is
+// false if no template matches, in which case name should be treated as an
+// ordinary, non-templated measurement name.
+func (m *TemplateMapper) Match(name string) (measurement string, tags map[string]string, field string, ok bool) {
+	parts := strings.Split(name, ".")
+	for _, t := range m.templates {
+		if !t.matches(parts) {
+			continue
+		}
+		measurement, tags, field = t.apply(parts)
+		return measurement, tags, field, true
+	}
+	return "", nil, "", false
+}
+
+func (t *Template) matches(parts []string) bool {
+	if len(parts) != len(t.Parts) {
+		return false
+	}
+	for i, f := range t.Filter {
+		if i >= len(parts) {
+			return false
+		}
+		if f != "*" && f != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Template) apply(parts []string) (measurement string, tags map[string]string, field string) {
+	tags = make(map[string]string, len(t.Tags))
+	for k, v := range t.Tags {
+		tags[k] = v
+	}
+
+	for i, p := range t.Parts {
+		switch p {
+		case "measurement":
+			measurement = parts[i]
+		case "field":
+			field = parts[i]
+		case "*", "":
+			// Not captured as a tag.
+		default:
+			tags[p] = parts[i]
+		}
+	}
+	return measurement, tags, field
+}
@@ -0,0 +1,84 @@
+package influxql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/platform/query/influxql"
+)
+
+func TestTemplateMapper_Match(t *testing.T) {
+	tests := []struct {
+		name        string
+		templates   []string
+		measurement string
+
+		wantMeasurement string
+		wantTags        map[string]string
+		wantField       string
+		wantOK          bool
+	}{
+		{
+			name:            "simple decomposition",
+			templates:       []string{"region.host.measurement.field"},
+			measurement:     "us-west.web01.cpu.idle",
+			wantMeasurement: "cpu",
+			wantTags:        map[string]string{"region": "us-west", "host": "web01"},
+			wantField:       "idle",
+			wantOK:          true,
+		},
+		{
+			name:            "default tags are applied",
+			templates:       []string{"host.measurement.field dc=west"},
+			measurement:     "web01.cpu.idle",
+			wantMeasurement: "cpu",
+			wantTags:        map[string]string{"host": "web01", "dc": "west"},
+			wantField:       "idle",
+			wantOK:          true,
+		},
+		{
+			name: "first matching template wins on conflict",
+			templates: []string{
+				"env.measurement.field filter=prod.*",
+				"host.measurement.field",
+			},
+			measurement:     "prod.cpu.idle",
+			wantMeasurement: "cpu",
+			wantTags:        map[string]string{"env": "prod"},
+			wantField:       "idle",
+			wantOK:          true,
+		},
+		{
+			name:        "no template matches shape",
+			templates:   []string{"region.host.measurement.field"},
+			measurement: "cpu.idle",
+			wantOK:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := influxql.NewTemplateMapper(tt.templates)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			measurement, tags, field, ok := m.Match(tt.measurement)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok=%v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if measurement != tt.wantMeasurement {
+				t.Errorf("got measurement %q, want %q", measurement, tt.wantMeasurement)
+			}
+			if field != tt.wantField {
+				t.Errorf("got field %q, want %q", field, tt.wantField)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("got tags %v, want %v", tags, tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package influxql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
+)
+
+// transpiler rewrites a single InfluxQL SELECT statement into the
+// equivalent Flux query text, reading from mapping's bucket and, when
+// templates match, decomposing a dotted measurement name into tags.
+type transpiler struct {
+	mapping   *platform.DBRPMapping
+	templates *TemplateMapper
+}
+
+func newTranspiler(mapping *platform.DBRPMapping, templates *TemplateMapper) *transpiler {
+	return &transpiler{mapping: mapping, templates: templates}
+}
+
+// Transpile converts q, an InfluxQL query, into Flux source text.
+func (t *transpiler) Transpile(q string) (string, error) {
+	parsed, err := influxql.ParseQuery(q)
+	if err != nil {
+		return "", fmt.Errorf("invalid InfluxQL query: %w", err)
+	}
+	if len(parsed.Statements) != 1 {
+		return "", fmt.Errorf("expected exactly one statement, got %d", len(parsed.Statements))
+	}
+
+	stmt, ok := parsed.Statements[0].(*influxql.SelectStatement)
+	if !ok {
+		return "", fmt.Errorf("unsupported statement type %T", parsed.Statements[0])
+	}
+
+	return t.transpileSelect(stmt)
+}
+
+func (t *transpiler) transpileSelect(stmt *influxql.SelectStatement) (string, error) {
+	names, err := measurementNames(stmt.Sources)
+	if err != nil {
+		return "", err
+	}
+
+	var clauses []string
+	var extraFields []string
+	for _, name := range names {
+		clause, field, ok := t.templateClause(name)
+		if !ok {
+			clauses = append(clauses, fmt.Sprintf("r._measurement == %q", name))
+			continue
+		}
+		clauses = append(clauses, clause)
+		if field != "" {
+			extraFields = append(extraFields, field)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucketID: %q)\n", t.mapping.BucketID.String())
+	b.WriteString("\t|> range(start: 0)\n")
+
+	if len(clauses) > 0 {
+		fmt.Fprintf(&b, "\t|> filter(fn: (r) => %s)\n", strings.Join(clauses, " or "))
+	}
+
+	fields := dedupe(append(fieldNames(stmt.Fields), extraFields...))
+	if len(fields) > 0 {
+		b.WriteString("\t|> filter(fn: (r) => ")
+		for i, name := range fields {
+			if i > 0 {
+				b.WriteString(" or ")
+			}
+			fmt.Fprintf(&b, "r._field == %q", name)
+		}
+		b.WriteString(")\n")
+	}
+
+	return b.String(), nil
+}
+
+// templateClause decomposes name against t.templates, if any match, into a
+// Flux filter clause over the resulting tags (and, via the second return
+// value, the field captured from the template). ok is false when no
+// template matches name, in which case it should be filtered on as a plain
+// measurement name instead.
+func (t *transpiler) templateClause(name string) (clause, field string, ok bool) {
+	if t.templates == nil {
+		return "", "", false
+	}
+
+	measurement, tags, field, matched := t.templates.Match(name)
+	if !matched {
+		return "", "", false
+	}
+
+	var preds []string
+	if measurement != "" {
+		preds = append(preds, fmt.Sprintf("r._measurement == %q", measurement))
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		preds = append(preds, fmt.Sprintf("r.%s == %q", k, tags[k]))
+	}
+
+	return "(" + strings.Join(preds, " and ") + ")", field, true
+}
+
+// measurementNames extracts the plain measurement names referenced by an
+// InfluxQL FROM clause.
+func measurementNames(sources influxql.Sources) ([]string, error) {
+	names := make([]string, 0, len(sources))
+	for _, src := range sources {
+		m, ok := src.(*influxql.Measurement)
+		if !ok {
+			return nil, fmt.Errorf("unsupported source type %T", src)
+		}
+		names = append(names, m.Name)
+	}
+	return names, nil
+}
+
+// fieldNames extracts the bare field names selected by an InfluxQL SELECT
+// clause, skipping `*`.
+func fieldNames(fields influxql.Fields) []string {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if ref, ok := f.Expr.(*influxql.VarRef); ok && ref.Val != "*" {
+			names = append(names, ref.Val)
+		}
+	}
+	return names
+}
+
+// dedupe returns names with duplicates removed, preserving the order of
+// first occurrence. A field can appear twice when a template-captured field
+// is also named explicitly in the SELECT list.
+func dedupe(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := names[:0:0]
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package influxql
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/platform"
+)
+
+func TestTranspiler_GraphiteTemplate(t *testing.T) {
+	q, err := ioutil.ReadFile("testdata/graphite_template.influxql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := NewTemplateMapper([]string{"region.host.measurement.field"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapping := &platform.DBRPMapping{BucketID: [8]byte{0xda, 0x7a, 0xba, 0x5e, 0x5e, 0xed, 0xca, 0x5e}}
+	tr := newTranspiler(mapping, templates)
+
+	flux, err := tr.Transpile(string(q))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`r._measurement == "cpu"`,
+		`r.region == "us-west"`,
+		`r.host == "web01"`,
+		`r._field == "idle"`,
+	} {
+		if !strings.Contains(flux, want) {
+			t.Errorf("transpiled query missing %q, got:\n%s", want, flux)
+		}
+	}
+
+	// The query selects "idle", the same field the template captures from
+	// the measurement name, so it must appear in the _field filter only once.
+	if want := `r._field == "idle" or r._field == "idle"`; strings.Contains(flux, want) {
+		t.Errorf("transpiled query duplicates the _field filter, got:\n%s", flux)
+	}
+}
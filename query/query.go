@@ -0,0 +1,63 @@
+// Package query contains the types used to submit a compiled query for
+// execution and to shape the results back to a caller.
+package query
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	"github.com/influxdata/flux"
+)
+
+// Request represents the content required to compile and execute a query.
+type Request struct {
+	// Compiler converts the query into an executable flux.Program.
+	Compiler flux.Compiler
+}
+
+// ProxyRequest specifies a query request and the dialect that should be used
+// to encode the results back to the caller.
+type ProxyRequest struct {
+	// Request is the basic query request.
+	Request Request
+
+	// Dialect is the result encoder.
+	Dialect flux.Dialect
+
+	deadlineTimer
+}
+
+// Context returns a context derived from parent that is additionally
+// canceled when req's read or write deadline fires, surfacing
+// ErrDeadlineExceeded through the returned deadlineErr func so a caller can
+// tell a deadline from ordinary context cancellation.
+func (req *ProxyRequest) Context(parent context.Context) (ctx context.Context, cancel context.CancelFunc, deadlineErr func() error) {
+	ctx, cancel = context.WithCancel(parent)
+
+	var exceeded int32
+	readC, writeC := req.readCancelChannel(), req.writeCancelChannel()
+	go func() {
+		select {
+		case <-readC:
+		case <-writeC:
+		case <-ctx.Done():
+			return
+		}
+		atomic.StoreInt32(&exceeded, 1)
+		cancel()
+	}()
+
+	return ctx, cancel, func() error {
+		if atomic.LoadInt32(&exceeded) == 1 {
+			return ErrDeadlineExceeded
+		}
+		return ctx.Err()
+	}
+}
+
+// Querier compiles and executes a query, encoding the results with the
+// dialect carried on the request.
+type Querier interface {
+	Query(ctx context.Context, w io.Writer, compiler flux.Compiler, d flux.Dialect) (int64, error)
+}
@@ -0,0 +1,13 @@
+package testing
+
+import "github.com/influxdata/platform"
+
+// MustIDBase16 parses s, a 16 character hex string, into a platform.ID and
+// panics if s is not a valid ID. It exists to keep test fixtures terse.
+func MustIDBase16(s string) platform.ID {
+	id, err := platform.IDFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return *id
+}